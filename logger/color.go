@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+const (
+	colorReset   = "\x1b[0m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorRed     = "\x1b[31m"
+	colorMagenta = "\x1b[35m"
+	colorDim     = "\x1b[2m"
+)
+
+var useColor bool
+
+// setupColor resolves -logger.color into useColor, auto-detecting whether
+// the selected output is a terminal, and arranges for ANSI escapes to render
+// correctly on Windows consoles.
+func setupColor() {
+	switch *loggerColor {
+	case "always":
+		useColor = true
+	case "never":
+		useColor = false
+	default: // auto
+		useColor = isOutputTerminal()
+	}
+	if useColor {
+		enableVirtualTerminalProcessing()
+	}
+}
+
+func isOutputTerminal() bool {
+	var f *os.File
+	switch *loggerOutput {
+	case "stderr":
+		f = os.Stderr
+	case "stdout":
+		f = os.Stdout
+	default:
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+func levelColor(level string) string {
+	switch level {
+	case "INFO":
+		return colorGreen
+	case "WARN":
+		return colorYellow
+	case "ERROR":
+		return colorRed
+	case "FATAL", "PANIC":
+		return colorMagenta
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package logger
+
+// enableVirtualTerminalProcessing is a no-op outside of Windows, where
+// terminals already interpret ANSI escape codes natively.
+func enableVirtualTerminalProcessing() {}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package logger
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminalProcessing turns on ENABLE_VIRTUAL_TERMINAL_PROCESSING
+// for stdout/stderr so ANSI escape codes render instead of printing literally.
+func enableVirtualTerminalProcessing() {
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		handle := windows.Handle(f.Fd())
+		var mode uint32
+		if err := windows.GetConsoleMode(handle, &mode); err != nil {
+			continue
+		}
+		mode |= windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
+		windows.SetConsoleMode(handle, mode)
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Hook is the extension point for forwarding log entries to external systems
+// (syslog, a log-aggregation HTTP endpoint, etc). See the logger/syslog and
+// logger/http subpackages for ready-made implementations.
+type Hook interface {
+	// Levels returns the set of levels this hook wants to receive.
+	Levels() []string
+	// Fire is called for every logged Entry whose level is in Levels(). A
+	// returned error is logged once to stderr and otherwise ignored; Fire
+	// must never block logging on a slow or unreachable external system for
+	// long, and should do its own buffering/async dispatch if needed.
+	Fire(entry Entry) error
+}
+
+// Flusher is an optional interface a Hook may implement when it buffers
+// entries for asynchronous delivery (e.g. the logger/http hook). Fatalf and
+// Panicf call Flush on every hook that implements it before the process
+// exits, so a buffered hook doesn't lose the one message an operator most
+// wants shipped externally.
+type Flusher interface {
+	Flush() error
+}
+
+var (
+	hooksMu         sync.RWMutex
+	registeredHooks []Hook
+)
+
+// RegisterHook adds h to the set of hooks notified on every future log call
+// whose level is in h.Levels().
+func RegisterHook(h Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	registeredHooks = append(registeredHooks, h)
+}
+
+func fireHooks(entry Entry) {
+	hooksMu.RLock()
+	hooks := registeredHooks
+	hooksMu.RUnlock()
+
+	for _, h := range hooks {
+		if !levelIn(entry.Level, h.Levels()) {
+			continue
+		}
+		if err := h.Fire(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: hook error: %v\n", err)
+		}
+	}
+}
+
+// flushHooks drains every registered hook that buffers entries
+// asynchronously. Called before Fatalf/Panicf terminate the process.
+func flushHooks() {
+	hooksMu.RLock()
+	hooks := registeredHooks
+	hooksMu.RUnlock()
+
+	for _, h := range hooks {
+		f, ok := h.(Flusher)
+		if !ok {
+			continue
+		}
+		if err := f.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: hook flush error: %v\n", err)
+		}
+	}
+}
+
+func levelIn(level string, levels []string) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
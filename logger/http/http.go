@@ -0,0 +1,188 @@
+// Package http implements a logger.Hook that batches entries and POSTs them
+// as JSON to a configurable HTTP endpoint.
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/AnhaoROMA/webCrawler/logger"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	queueCapacity  = 1024
+)
+
+// Hook buffers entries and flushes them to url as a JSON array, either once
+// batchSize entries have accumulated or every flushInterval, whichever comes
+// first. Fire never blocks the logging call site: entries are handed off
+// over a buffered channel to a single background goroutine that owns the
+// actual HTTP dispatch, retries and backoff. Call Flush (or Close) from a
+// Fatalf/Panicf path to drain the queue before the process exits.
+type Hook struct {
+	url           string
+	levels        []string
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	client        *http.Client
+
+	entries   chan logger.Entry
+	flushReq  chan chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New starts the background flush loop and returns a Hook that forwards
+// entries whose level is in levels to url. It panics if batchSize or
+// flushInterval is non-positive, since run's ticker cannot be constructed
+// from a non-positive interval and the failure must surface at construction
+// time rather than crash the background goroutine later.
+func New(url string, levels []string, batchSize int, flushInterval time.Duration) *Hook {
+	if batchSize <= 0 {
+		panic(fmt.Errorf("FATAL: http hook: batchSize must be > 0, got %d", batchSize))
+	}
+	if flushInterval <= 0 {
+		panic(fmt.Errorf("FATAL: http hook: flushInterval must be > 0, got %v", flushInterval))
+	}
+
+	h := &Hook{
+		url:           url,
+		levels:        levels,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		maxRetries:    3,
+		client:        &http.Client{Timeout: defaultTimeout},
+		entries:       make(chan logger.Entry, queueCapacity),
+		flushReq:      make(chan chan error),
+		done:          make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// Levels implements logger.Hook.
+func (h *Hook) Levels() []string {
+	return h.levels
+}
+
+// Fire implements logger.Hook. It never blocks: the entry is handed to the
+// background sender over a buffered channel. If the queue is full the entry
+// is dropped and an error is returned so the caller sees it was lost, rather
+// than stalling an arbitrary crawler goroutine on a slow or unreachable
+// endpoint.
+func (h *Hook) Fire(entry logger.Entry) error {
+	select {
+	case h.entries <- entry:
+		return nil
+	default:
+		return fmt.Errorf("http hook: queue full (%d), dropping entry", queueCapacity)
+	}
+}
+
+// Flush blocks until every entry queued so far has been posted (exhausting
+// retries if necessary) and reports the outcome. Safe to call concurrently
+// with Fire.
+func (h *Hook) Flush() error {
+	reply := make(chan error, 1)
+	select {
+	case h.flushReq <- reply:
+		return <-reply
+	case <-h.done:
+		return fmt.Errorf("http hook: closed")
+	}
+}
+
+// Close flushes any queued entries and stops the background goroutine.
+func (h *Hook) Close() error {
+	err := h.Flush()
+	h.closeOnce.Do(func() { close(h.done) })
+	return err
+}
+
+func (h *Hook) run() {
+	ticker := time.NewTicker(h.flushInterval)
+	defer ticker.Stop()
+
+	var buf []logger.Entry
+	for {
+		select {
+		case entry := <-h.entries:
+			buf = append(buf, entry)
+			if len(buf) >= h.batchSize {
+				h.send(buf)
+				buf = nil
+			}
+		case <-ticker.C:
+			if len(buf) > 0 {
+				h.send(buf)
+				buf = nil
+			}
+		case reply := <-h.flushReq:
+			buf = append(buf, drainPending(h.entries)...)
+			var err error
+			if len(buf) > 0 {
+				err = h.send(buf)
+				buf = nil
+			}
+			reply <- err
+		case <-h.done:
+			return
+		}
+	}
+}
+
+func drainPending(entries <-chan logger.Entry) []logger.Entry {
+	var drained []logger.Entry
+	for {
+		select {
+		case entry := <-entries:
+			drained = append(drained, entry)
+		default:
+			return drained
+		}
+	}
+}
+
+// send posts batch, retrying with exponential backoff. Since it runs on the
+// background goroutine (never in a Fire caller's goroutine), a permanent
+// failure is reported to stderr directly rather than silently dropped.
+func (h *Hook) send(batch []logger.Entry) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: http hook: failed to marshal batch: %v\n", err)
+		return err
+	}
+
+	backoff := 500 * time.Millisecond
+	var lastErr error
+	for attempt := 0; attempt <= h.maxRetries; attempt++ {
+		if lastErr = h.post(data); lastErr == nil {
+			return nil
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	err = fmt.Errorf("http hook: giving up after %d attempts: %w", h.maxRetries+1, lastErr)
+	fmt.Fprintf(os.Stderr, "logger: %v\n", err)
+	return err
+}
+
+func (h *Hook) post(data []byte) error {
+	resp, err := h.client.Post(h.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("http hook: server returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package http
+
+import "testing"
+
+func TestNewPanicsOnNonPositiveBatchSizeOrFlushInterval(t *testing.T) {
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: New() did not panic", name)
+			}
+		}()
+		fn()
+	}
+
+	mustPanic("zero batchSize", func() { New("http://example.invalid", nil, 0, defaultTimeout) })
+	mustPanic("negative batchSize", func() { New("http://example.invalid", nil, -1, defaultTimeout) })
+	mustPanic("zero flushInterval", func() { New("http://example.invalid", nil, 10, 0) })
+	mustPanic("negative flushInterval", func() { New("http://example.invalid", nil, 10, -1) })
+}
@@ -1,25 +1,48 @@
 package logger
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
 var (
-	loggerLevel  = flag.String("logger.level", "INFO", "Minimum level to log. Possible values: INFO, WARN, ERROR, FATAL, PANIC.")
-	loggerOutput = flag.String("logger.output", "stderr", "Output for the logs. Supported values: stderr, stdout.")
-	mu           sync.Mutex
-	output       io.Writer = os.Stderr
-	timezone               = time.UTC
+	loggerLevel           = flag.String("logger.level", "INFO", "Minimum level to log. Possible values: TRACE, DEBUG, INFO, WARN, ERROR, FATAL, PANIC.")
+	loggerOutput          = flag.String("logger.output", "stderr", "Output for the logs. Supported values: stderr, stdout, file:<path>.")
+	loggerFormat          = flag.String("logger.format", "plain", "Output format for the logs. Supported values: plain, json.")
+	loggerPerPackageLevel = flag.String("logger.perPackageLevel", "", "Comma-separated list of per-package level overrides, e.g. `crawler/fetcher=DEBUG,crawler/parser=WARN`.")
+	loggerMaxSizeMB       = flag.Int("logger.maxSizeMB", 100, "Maximum size in megabytes of the log file before it gets rotated. Only applies to `-logger.output=file:...`.")
+	loggerMaxBackups      = flag.Int("logger.maxBackups", 5, "Maximum number of rotated log files to retain. 0 means retain all. Only applies to `-logger.output=file:...`.")
+	loggerMaxAgeDays      = flag.Int("logger.maxAgeDays", 0, "Maximum age in days to retain rotated log files. 0 means no age-based cleanup. Only applies to `-logger.output=file:...`.")
+	loggerCompress        = flag.Bool("logger.compress", false, "Whether to gzip rotated log files. Only applies to `-logger.output=file:...`.")
+	loggerColor           = flag.String("logger.color", "auto", "Whether to colorize plain-text log output. Possible values: auto, always, never.")
+	loggerBurst           = flag.Int("logger.burst", 20, "Token-bucket capacity for the per-callsite log rate limiter.")
+	loggerPerSecond       = flag.Float64("logger.perSecond", 5, "Token-bucket refill rate, in tokens per second, for the per-callsite log rate limiter.")
+	mu                    sync.Mutex
+	output                io.Writer = os.Stderr
+	timezone                        = time.UTC
+	perPackageLevels      map[string]string
 )
 
+// levelSeverity orders levels from most to least verbose.
+var levelSeverity = map[string]int{
+	"TRACE": 0,
+	"DEBUG": 1,
+	"INFO":  2,
+	"WARN":  3,
+	"ERROR": 4,
+	"FATAL": 5,
+	"PANIC": 6,
+}
+
 const (
 	programRootPath       = "/webCrawler/"
 	programRootPathLength = len(programRootPath)
@@ -27,26 +50,81 @@ const (
 
 func Initialization() {
 	validateLoggerLevel()
+	validateLoggerFormat()
+	validateLoggerColor()
+	validateLoggerRateLimit()
+	parsePerPackageLevel()
 	setLoggerOutput()
+	setupColor()
 }
 
 func validateLoggerLevel() {
-	switch *loggerLevel {
-	case "INFO", "WARN", "ERROR", "FATAL", "PANIC":
+	if _, ok := levelSeverity[*loggerLevel]; !ok {
+		// We cannot use logger.Panicf here, since the logger hasn't been initialized yet.
+		panic(fmt.Errorf("FATAL: unsupported `-logger.level` value: %q; supported values are: TRACE, DEBUG, INFO, WARN, ERROR, FATAL, PANIC", *loggerLevel))
+	}
+}
+
+func parsePerPackageLevel() {
+	perPackageLevels = make(map[string]string)
+	if *loggerPerPackageLevel == "" {
+		return
+	}
+	for _, entry := range strings.Split(*loggerPerPackageLevel, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, level, found := strings.Cut(entry, "=")
+		if !found {
+			panic(fmt.Errorf("FATAL: unsupported `-logger.perPackageLevel` entry: %q; expected format is `pkg/path=LEVEL`", entry))
+		}
+		if _, ok := levelSeverity[level]; !ok {
+			panic(fmt.Errorf("FATAL: unsupported `-logger.perPackageLevel` level: %q; supported values are: TRACE, DEBUG, INFO, WARN, ERROR, FATAL, PANIC", level))
+		}
+		perPackageLevels[prefix] = level
+	}
+}
+
+func validateLoggerFormat() {
+	switch *loggerFormat {
+	case "plain", "json":
 	default:
 		// We cannot use logger.Panicf here, since the logger hasn't been initialized yet.
-		panic(fmt.Errorf("FATAL: unsupported `-loggerLevel` value: %q; supported values are: INFO, WARN, ERROR, FATAL, PANIC", *loggerLevel))
+		panic(fmt.Errorf("FATAL: unsupported `-logger.format` value: %q; supported values are: plain, json", *loggerFormat))
+	}
+}
+
+func validateLoggerColor() {
+	switch *loggerColor {
+	case "auto", "always", "never":
+	default:
+		// We cannot use logger.Panicf here, since the logger hasn't been initialized yet.
+		panic(fmt.Errorf("FATAL: unsupported `-logger.color` value: %q; supported values are: auto, always, never", *loggerColor))
+	}
+}
+
+func validateLoggerRateLimit() {
+	if *loggerBurst <= 0 {
+		// We cannot use logger.Panicf here, since the logger hasn't been initialized yet.
+		panic(fmt.Errorf("FATAL: unsupported `-logger.burst` value: %d; must be > 0", *loggerBurst))
+	}
+	if *loggerPerSecond < 0 {
+		panic(fmt.Errorf("FATAL: unsupported `-logger.perSecond` value: %v; must be >= 0", *loggerPerSecond))
 	}
 }
 
 func setLoggerOutput() {
-	switch *loggerOutput {
-	case "stderr":
+	switch {
+	case *loggerOutput == "stderr":
 		output = os.Stderr
-	case "stdout":
+	case *loggerOutput == "stdout":
 		output = os.Stdout
+	case strings.HasPrefix(*loggerOutput, "file:"):
+		path := strings.TrimPrefix(*loggerOutput, "file:")
+		output = newRotatingWriter(path, *loggerMaxSizeMB, *loggerMaxBackups, *loggerMaxAgeDays, *loggerCompress)
 	default:
-		panic(fmt.Errorf("FATAL: unsupported `loggerOutput` value: %q; supported values are: stderr, stdout", *loggerOutput))
+		panic(fmt.Errorf("FATAL: unsupported `-logger.output` value: %q; supported values are: stderr, stdout, file:<path>", *loggerOutput))
 	}
 }
 
@@ -55,6 +133,16 @@ func Infof(format string, args ...interface{}) {
 	logLevel("INFO", format, args)
 }
 
+// Debugf logs debug message.
+func Debugf(format string, args ...interface{}) {
+	logLevel("DEBUG", format, args)
+}
+
+// Tracef logs trace message.
+func Tracef(format string, args ...interface{}) {
+	logLevel("TRACE", format, args)
+}
+
 // Warnf logs warn message.
 func Warnf(format string, args ...interface{}) {
 	logLevel("WARN", format, args)
@@ -75,12 +163,91 @@ func Panicf(format string, args ...interface{}) {
 	logLevel("PANIC", format, args)
 }
 
-func logLevel(level, format string, args []interface{}) {
-	if shouldSkipLog(level) {
-		return
+// Entry carries a set of contextual fields to attach to every message logged
+// through it, via WithField or WithFields. It also doubles as the record
+// handed to registered Hooks, in which case Time, Level, File, Line and Msg
+// are populated and Fields holds whatever was attached when it was logged.
+type Entry struct {
+	Time   time.Time
+	Level  string
+	File   string
+	Line   int
+	Msg    string
+	Fields map[string]interface{}
+}
+
+// WithField returns an Entry with the given key/value attached as a field.
+func WithField(key string, value interface{}) *Entry {
+	return &Entry{Fields: map[string]interface{}{key: value}}
+}
+
+// WithFields returns an Entry with the given fields attached.
+func WithFields(fields map[string]interface{}) *Entry {
+	var merged map[string]interface{} = make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		merged[k] = v
 	}
-	var msg string = formatLogMessage(format, args)
-	logMessage(level, msg, 3)
+	return &Entry{Fields: merged}
+}
+
+// WithField returns a new Entry with the given key/value added to its fields.
+func (e *Entry) WithField(key string, value interface{}) *Entry {
+	return e.WithFields(map[string]interface{}{key: value})
+}
+
+// WithFields returns a new Entry with the given fields merged into its fields.
+func (e *Entry) WithFields(fields map[string]interface{}) *Entry {
+	var merged map[string]interface{} = make(map[string]interface{}, len(e.Fields)+len(fields))
+	for k, v := range e.Fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Entry{Fields: merged}
+}
+
+// Infof logs info message with the entry's fields attached.
+func (e *Entry) Infof(format string, args ...interface{}) {
+	logLevelFields("INFO", format, args, e.Fields)
+}
+
+// Debugf logs debug message with the entry's fields attached.
+func (e *Entry) Debugf(format string, args ...interface{}) {
+	logLevelFields("DEBUG", format, args, e.Fields)
+}
+
+// Tracef logs trace message with the entry's fields attached.
+func (e *Entry) Tracef(format string, args ...interface{}) {
+	logLevelFields("TRACE", format, args, e.Fields)
+}
+
+// Warnf logs warn message with the entry's fields attached.
+func (e *Entry) Warnf(format string, args ...interface{}) {
+	logLevelFields("WARN", format, args, e.Fields)
+}
+
+// Errorf logs error message with the entry's fields attached.
+func (e *Entry) Errorf(format string, args ...interface{}) {
+	logLevelFields("ERROR", format, args, e.Fields)
+}
+
+// Fatalf logs fatal message with the entry's fields attached and terminates the app.
+func (e *Entry) Fatalf(format string, args ...interface{}) {
+	logLevelFields("FATAL", format, args, e.Fields)
+}
+
+// Panicf logs panic message with the entry's fields attached and panics.
+func (e *Entry) Panicf(format string, args ...interface{}) {
+	logLevelFields("PANIC", format, args, e.Fields)
+}
+
+func logLevel(level, format string, args []interface{}) {
+	logMessage(level, format, args, nil, 3)
+}
+
+func logLevelFields(level, format string, args []interface{}, fields map[string]interface{}) {
+	logMessage(level, format, args, fields, 3)
 }
 
 func formatLogMessage(format string, args []interface{}) string {
@@ -88,8 +255,9 @@ func formatLogMessage(format string, args []interface{}) string {
 	return fmt.Sprintf(format, args...)
 }
 
-func logMessage(level, msg string, skipframes int) {
-	var timestamp string = time.Now().In(timezone).Format("2006-01-02T15:04:05.000Z0700")
+func logMessage(level, format string, args []interface{}, fields map[string]interface{}, skipframes int) {
+	var now time.Time = time.Now().In(timezone)
+	var timestamp string = now.Format("2006-01-02T15:04:05.000Z0700")
 
 	_, file, line, ok := runtime.Caller(skipframes)
 	if !ok {
@@ -101,53 +269,130 @@ func logMessage(level, msg string, skipframes int) {
 		// Strip /secretRotation/ prefix
 		file = file[n+programRootPathLength:]
 	}
+
+	// Resolve the effective level (and below, the rate limiter) off the raw
+	// format/args before ever formatting the message, so a disabled DEBUG/TRACE
+	// call site costs a caller lookup, not a Sprintf.
+	if shouldSkipLog(level, file) {
+		return
+	}
+
 	location := fmt.Sprintf("%s:%d", file, line)
 
-	// Might add a suppression feature here in the future.
+	// FATAL/PANIC must always run to completion regardless of how noisy the
+	// callsite is: rate-limiting them could silently drop the os.Exit/panic
+	// a caller relies on to actually terminate the process.
+	var suppressed int64
+	if level != "FATAL" && level != "PANIC" {
+		var allowed bool
+		allowed, suppressed = rateLimitAllow(level, location, format)
+		if !allowed {
+			return
+		}
+	}
+
+	msg := formatLogMessage(format, args)
+	if suppressed > 0 {
+		msg = fmt.Sprintf("[suppressed %d prior] %s", suppressed, msg)
+	}
 
 	for len(msg) > 0 && msg[len(msg)-1] == '\n' {
 		msg = msg[:len(msg)-1]
 	}
-	var logMsg string = fmt.Sprintf("%s\t%s\t%s\t%s\n", timestamp, level, location, msg)
+
+	var logMsg string
+	if *loggerFormat == "json" {
+		logMsg = formatJSONMessage(timestamp, level, location, msg, fields)
+	} else {
+		logMsg = formatPlainMessage(timestamp, level, location, msg, fields)
+	}
+
 	// Serialize writes to log.
 	mu.Lock()
 	fmt.Fprint(output, logMsg)
 	mu.Unlock()
 
+	fireHooks(Entry{Time: now, Level: level, File: file, Line: line, Msg: msg, Fields: fields})
+
 	switch level {
 	case "PANIC":
+		flushHooks()
 		panic(errors.New(msg))
 	case "FATAL":
+		flushHooks()
 		os.Exit(-1)
 	}
 }
 
-func shouldSkipLog(level string) bool {
-	switch *loggerLevel {
-	case "WARN":
-		switch level {
-		case "WARN", "ERROR", "FATAL", "PANIC":
-			return false
-		default:
-			return true
+func formatPlainMessage(timestamp, level, location, msg string, fields map[string]interface{}) string {
+	var levelStr string = level
+	var locationStr string = location
+	if useColor {
+		if c := levelColor(level); c != "" {
+			levelStr = c + level + colorReset
 		}
-	case "ERROR":
-		switch level {
-		case "ERROR", "FATAL", "PANIC":
-			return false
-		default:
-			return true
+		locationStr = colorDim + location + colorReset
+	}
+	if len(fields) == 0 {
+		return fmt.Sprintf("%s\t%s\t%s\t%s\n", timestamp, levelStr, locationStr, msg)
+	}
+	var fieldsStr string = formatPlainFields(fields)
+	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", timestamp, levelStr, locationStr, msg, fieldsStr)
+}
+
+func formatPlainFields(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
 		}
-	case "FATAL":
-		switch level {
-		case "FATAL", "PANIC":
-			return false
-		default:
-			return true
+		fmt.Fprintf(&b, "%s=%v", k, fields[k])
+	}
+	return b.String()
+}
+
+func formatJSONMessage(timestamp, level, location, msg string, fields map[string]interface{}) string {
+	record := make(map[string]interface{}, len(fields)+4)
+	for k, v := range fields {
+		record[k] = v
+	}
+	record["time"] = timestamp
+	record["level"] = level
+	record["caller"] = location
+	record["msg"] = msg
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		// Fall back to a plain record so a bad field value never drops the log line.
+		return fmt.Sprintf(`{"time":%q,"level":%q,"caller":%q,"msg":%q}`+"\n", timestamp, level, location, msg)
+	}
+	return string(data) + "\n"
+}
+
+func shouldSkipLog(level, file string) bool {
+	return levelSeverity[level] < levelSeverity[effectiveLevel(file)]
+}
+
+// effectiveLevel returns the minimum level to log for the given (already
+// programRootPath-stripped) file path, honoring -logger.perPackageLevel
+// overrides. The longest matching package prefix wins.
+func effectiveLevel(file string) string {
+	var best string
+	var bestLen int = -1
+	for prefix, level := range perPackageLevels {
+		if strings.HasPrefix(file, prefix) && len(prefix) > bestLen {
+			best = level
+			bestLen = len(prefix)
 		}
-	case "PANIC":
-		return level != "PANIC"
-	default:
-		return false
 	}
+	if bestLen < 0 {
+		return *loggerLevel
+	}
+	return best
 }
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFormatPlainFieldsIsSortedAndDeterministic(t *testing.T) {
+	fields := map[string]interface{}{"b": 2, "a": 1, "c": "three"}
+
+	want := "a=1 b=2 c=three"
+	for i := 0; i < 10; i++ {
+		if got := formatPlainFields(fields); got != want {
+			t.Fatalf("formatPlainFields() = %q, want %q (iteration %d)", got, want, i)
+		}
+	}
+}
+
+func TestFormatPlainMessageWithAndWithoutFields(t *testing.T) {
+	withoutFields := formatPlainMessage("2026-01-01T00:00:00.000Z", "INFO", "main.go:1", "hello", nil)
+	if strings.Count(withoutFields, "\t") != 3 {
+		t.Fatalf("formatPlainMessage() without fields = %q, want exactly 4 tab-separated columns", withoutFields)
+	}
+
+	withFields := formatPlainMessage("2026-01-01T00:00:00.000Z", "INFO", "main.go:1", "hello", map[string]interface{}{"k": "v"})
+	if !strings.HasSuffix(withFields, "\tk=v\n") {
+		t.Fatalf("formatPlainMessage() with fields = %q, want it to end with the rendered fields", withFields)
+	}
+}
+
+func TestFormatJSONMessageReservedKeysTakePrecedenceOverFields(t *testing.T) {
+	// A contextual field sharing a name with a reserved top-level key (e.g. an
+	// operator doing WithField("msg", ...)) must not be able to clobber the
+	// actual message/level/time/caller in the emitted record.
+	fields := map[string]interface{}{"msg": "attacker-controlled", "user": "alice"}
+
+	raw := formatJSONMessage("2026-01-01T00:00:00.000Z", "INFO", "main.go:1", "the real message", fields)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		t.Fatalf("formatJSONMessage() produced invalid JSON: %v (%q)", err, raw)
+	}
+
+	if record["msg"] != "the real message" {
+		t.Errorf("record[\"msg\"] = %v, want the logged message, not the field value", record["msg"])
+	}
+	if record["level"] != "INFO" {
+		t.Errorf("record[\"level\"] = %v, want INFO", record["level"])
+	}
+	if record["user"] != "alice" {
+		t.Errorf("record[\"user\"] = %v, want alice", record["user"])
+	}
+}
+
+func TestShouldSkipLogHonorsPerPackageOverride(t *testing.T) {
+	origLevel := *loggerLevel
+	origPerPackage := perPackageLevels
+	defer func() {
+		*loggerLevel = origLevel
+		perPackageLevels = origPerPackage
+	}()
+
+	*loggerLevel = "WARN"
+	perPackageLevels = map[string]string{"crawler/fetcher": "DEBUG"}
+
+	if shouldSkipLog("DEBUG", "crawler/fetcher/fetch.go") {
+		t.Error("shouldSkipLog() skipped a DEBUG message under a package override that allows DEBUG")
+	}
+	if !shouldSkipLog("DEBUG", "crawler/parser/parse.go") {
+		t.Error("shouldSkipLog() let a DEBUG message through outside the overridden package, under global level WARN")
+	}
+	if shouldSkipLog("WARN", "crawler/parser/parse.go") {
+		t.Error("shouldSkipLog() skipped a WARN message at the global WARN level")
+	}
+}
+
+func TestPanicfIsNeverThrottledByTheRateLimiter(t *testing.T) {
+	origBurst, origPerSecond := *loggerBurst, *loggerPerSecond
+	origOutput := output
+	*loggerBurst = 1
+	*loggerPerSecond = 0
+	output = io.Discard
+	defer func() {
+		*loggerBurst = origBurst
+		*loggerPerSecond = origPerSecond
+		output = origOutput
+	}()
+
+	// A burst of 1 with no refill means every call past the first one would be
+	// throttled for an ordinary level. Panicf must still panic every single
+	// time, regardless of how many times this exact callsite has fired.
+	const calls = 25
+	for i := 0; i < calls; i++ {
+		panicked := func() (panicked bool) {
+			defer func() {
+				if recover() != nil {
+					panicked = true
+				}
+			}()
+			Panicf("boom %d", i)
+			return false
+		}()
+		if !panicked {
+			t.Fatalf("Panicf() call #%d did not panic; the rate limiter must have throttled it", i)
+		}
+	}
+}
+
+func TestValidateLoggerRateLimitRejectsBadFlags(t *testing.T) {
+	origBurst, origPerSecond := *loggerBurst, *loggerPerSecond
+	defer func() {
+		*loggerBurst = origBurst
+		*loggerPerSecond = origPerSecond
+	}()
+
+	mustPanic := func(name string) {
+		t.Helper()
+		if recover() == nil {
+			t.Errorf("%s: validateLoggerRateLimit() did not panic on an invalid flag value", name)
+		}
+	}
+
+	func() {
+		defer mustPanic("zero burst")
+		*loggerBurst, *loggerPerSecond = 0, 5
+		validateLoggerRateLimit()
+	}()
+	func() {
+		defer mustPanic("negative burst")
+		*loggerBurst, *loggerPerSecond = -1, 5
+		validateLoggerRateLimit()
+	}()
+	func() {
+		defer mustPanic("negative perSecond")
+		*loggerBurst, *loggerPerSecond = 20, -1
+		validateLoggerRateLimit()
+	}()
+
+	*loggerBurst, *loggerPerSecond = 20, 0
+	validateLoggerRateLimit() // should not panic: zero refill rate is a valid "never refill" configuration
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// rateLimitKey identifies a callsite for rate-limiting purposes. formatPtr is
+// the format string's underlying data pointer rather than the formatted
+// message, so computing the key never allocates per call.
+type rateLimitKey struct {
+	level     string
+	location  string
+	formatPtr uintptr
+}
+
+var rateLimitBuckets sync.Map // rateLimitKey -> *tokenBucket
+
+// tokenBucket is a simple token-bucket rate limiter with a running count of
+// messages suppressed since the last one that was allowed through.
+type tokenBucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	last       time.Time
+	suppressed int64
+}
+
+// rateLimitAllow reports whether the message for the given callsite may be
+// logged, and if so, how many prior messages from that callsite were
+// suppressed since the last one that got through.
+func rateLimitAllow(level, location, format string) (allowed bool, suppressedPrior int64) {
+	key := rateLimitKey{level: level, location: location, formatPtr: stringDataPointer(format)}
+
+	value, _ := rateLimitBuckets.LoadOrStore(key, &tokenBucket{tokens: float64(*loggerBurst), last: time.Now()})
+	bucket := value.(*tokenBucket)
+	return bucket.allow()
+}
+
+func (b *tokenBucket) allow() (bool, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var capacity float64 = float64(*loggerBurst)
+	var rate float64 = *loggerPerSecond
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		b.suppressed++
+		return false, 0
+	}
+	b.tokens--
+	var suppressedPrior int64 = b.suppressed
+	b.suppressed = 0
+	return true, suppressedPrior
+}
+
+// stringDataPointer returns the address of a Go string's backing bytes. It is
+// used only as an opaque, allocation-free identity key for a format string
+// literal, never dereferenced.
+func stringDataPointer(s string) uintptr {
+	type stringHeader struct {
+		data unsafe.Pointer
+		len  int
+	}
+	return uintptr((*stringHeader)(unsafe.Pointer(&s)).data)
+}
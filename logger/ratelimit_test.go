@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func withRateLimitFlags(t *testing.T, burst int, perSecond float64) {
+	t.Helper()
+	origBurst, origPerSecond := *loggerBurst, *loggerPerSecond
+	*loggerBurst = burst
+	*loggerPerSecond = perSecond
+	t.Cleanup(func() {
+		*loggerBurst = origBurst
+		*loggerPerSecond = origPerSecond
+	})
+}
+
+func TestTokenBucketAllowsUpToBurstThenThrottles(t *testing.T) {
+	withRateLimitFlags(t, 3, 0) // no refill, so behavior is pinned to the initial burst
+
+	b := &tokenBucket{tokens: 3, last: time.Now()}
+
+	for i := 0; i < 3; i++ {
+		allowed, suppressed := b.allow()
+		if !allowed {
+			t.Fatalf("allow() #%d = false, want true within burst capacity", i)
+		}
+		if suppressed != 0 {
+			t.Fatalf("allow() #%d suppressedPrior = %d, want 0", i, suppressed)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := b.allow(); allowed {
+			t.Fatalf("allow() past burst capacity (call #%d) = true, want throttled", i)
+		}
+	}
+
+	if b.suppressed != 2 {
+		t.Fatalf("suppressed count = %d, want 2", b.suppressed)
+	}
+}
+
+func TestTokenBucketReportsSuppressedCountOnNextAllowedMessage(t *testing.T) {
+	withRateLimitFlags(t, 1, 5)
+
+	b := &tokenBucket{tokens: 1, last: time.Now()}
+
+	if allowed, suppressed := b.allow(); !allowed || suppressed != 0 {
+		t.Fatalf("first allow() = (%v, %d), want (true, 0)", allowed, suppressed)
+	}
+	for i := 0; i < 3; i++ {
+		if allowed, _ := b.allow(); allowed {
+			t.Fatalf("allow() #%d should have been throttled with no time having elapsed", i)
+		}
+	}
+
+	// Simulate 1 second of elapsed time so the bucket refills by perSecond=5 tokens.
+	b.last = b.last.Add(-1 * time.Second)
+
+	allowed, suppressed := b.allow()
+	if !allowed {
+		t.Fatal("allow() after refill = false, want true")
+	}
+	if suppressed != 3 {
+		t.Fatalf("suppressedPrior = %d, want 3 (the throttled calls since the last allowed one)", suppressed)
+	}
+}
+
+func TestTokenBucketNeverExceedsCapacity(t *testing.T) {
+	withRateLimitFlags(t, 2, 100)
+
+	b := &tokenBucket{tokens: 2, last: time.Now().Add(-time.Hour)} // huge elapsed time, refill should still cap at capacity
+
+	allowed, suppressed := b.allow()
+	if !allowed || suppressed != 0 {
+		t.Fatalf("allow() = (%v, %d), want (true, 0)", allowed, suppressed)
+	}
+	if b.tokens+1 > 2 {
+		t.Fatalf("tokens = %v, want capped at burst capacity 2", b.tokens+1) // +1 to account for the token just spent
+	}
+}
+
+func TestRateLimitAllowKeysByLevelLocationAndFormatPointer(t *testing.T) {
+	withRateLimitFlags(t, 1, 0)
+
+	const locationA = "TestRateLimitAllowKeysByLevelLocationAndFormatPointer:a"
+	const locationB = "TestRateLimitAllowKeysByLevelLocationAndFormatPointer:b"
+	formatA := "format A"
+	formatB := "format B"
+
+	if allowed, _ := rateLimitAllow("INFO", locationA, formatA); !allowed {
+		t.Fatal("first call for locationA = throttled, want allowed")
+	}
+	if allowed, _ := rateLimitAllow("INFO", locationA, formatA); allowed {
+		t.Fatal("second call for the same callsite = allowed, want throttled (burst exhausted)")
+	}
+
+	// A distinct location is an independent bucket, unaffected by locationA's exhausted burst.
+	if allowed, _ := rateLimitAllow("INFO", locationB, formatA); !allowed {
+		t.Fatal("call for a distinct location = throttled, want allowed")
+	}
+
+	// A distinct format string at the same location is also an independent bucket.
+	if allowed, _ := rateLimitAllow("INFO", locationA, formatB); !allowed {
+		t.Fatal("call with a distinct format string at the same location = throttled, want allowed")
+	}
+}
+
+func TestStringDataPointerDiffersForDistinctStrings(t *testing.T) {
+	a := "some distinct format string"
+	b := "a completely different format string"
+	if stringDataPointer(a) == stringDataPointer(b) {
+		t.Fatal("stringDataPointer() returned the same pointer for two distinct string literals")
+	}
+}
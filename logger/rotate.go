@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// renameFile is os.Rename, indirected so tests can simulate a failed
+// rotation rename without needing an actual unwritable filesystem.
+var renameFile = os.Rename
+
+// rotatingWriter is an io.Writer backed by a file on disk that rotates to a
+// timestamped backup once it would grow past maxSizeBytes. Callers must only
+// invoke Write while holding the package-level mu lock; rotatingWriter itself
+// does no locking.
+type rotatingWriter struct {
+	path         string
+	maxSizeBytes int64
+	maxBackups   int
+	maxAgeDays   int
+	compress     bool
+
+	file *os.File
+	size int64
+}
+
+func newRotatingWriter(path string, maxSizeMB, maxBackups, maxAgeDays int, compress bool) *rotatingWriter {
+	w := &rotatingWriter{
+		path:         path,
+		maxSizeBytes: int64(maxSizeMB) * 1024 * 1024,
+		maxBackups:   maxBackups,
+		maxAgeDays:   maxAgeDays,
+		compress:     compress,
+	}
+	if err := w.open(); err != nil {
+		panic(fmt.Errorf("FATAL: cannot open log file %q: %w", path, err))
+	}
+	return w
+}
+
+// open opens (or creates) the active log file and records its current size.
+func (w *rotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	if w.maxSizeBytes > 0 && w.size+int64(len(p)) > w.maxSizeBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate closes the active file, atomically renames it to a timestamped
+// backup, reopens a fresh active file, and prunes old backups.
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	timestamp := time.Now().In(timezone).Format("20060102T150405.000")
+	backupPath := fmt.Sprintf("%s.%s", w.path, timestamp)
+	renamed := true
+	if err := renameFile(w.path, backupPath); err != nil {
+		// A failed rename leaves the original file at w.path; reopen it below
+		// rather than giving up, so a transient rename failure doesn't take
+		// file-based logging down permanently.
+		renamed = false
+		fmt.Fprintf(os.Stderr, "logger: failed to rotate %q: %v\n", w.path, err)
+	}
+
+	if renamed && w.compress {
+		if err := compressFile(backupPath); err != nil {
+			// Failing to compress a backup shouldn't stop logging from continuing.
+			fmt.Fprintf(os.Stderr, "logger: failed to compress %q: %v\n", backupPath, err)
+		}
+	}
+
+	if err := w.open(); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: failed to reopen log file %q after rotation: %v\n", w.path, err)
+		return err
+	}
+
+	if renamed {
+		w.pruneBackups()
+	}
+	return nil
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		gw.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// pruneBackups removes rotated backups of w.path that exceed maxBackups or
+// are older than maxAgeDays. Failures to remove a given backup are logged
+// and otherwise ignored.
+func (w *rotatingWriter) pruneBackups() {
+	if w.maxBackups <= 0 && w.maxAgeDays <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(w.path)
+	prefix := filepath.Base(w.path) + "."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: failed to list %q for rotation cleanup: %v\n", dir, err)
+		return
+	}
+
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, entry.Name()), modTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+
+	if w.maxAgeDays > 0 {
+		cutoff := time.Now().In(timezone).AddDate(0, 0, -w.maxAgeDays)
+		var kept []backup
+		for _, b := range backups {
+			if b.modTime.Before(cutoff) {
+				if err := os.Remove(b.path); err != nil {
+					fmt.Fprintf(os.Stderr, "logger: failed to remove aged-out backup %q: %v\n", b.path, err)
+				}
+				continue
+			}
+			kept = append(kept, b)
+		}
+		backups = kept
+	}
+
+	if w.maxBackups > 0 && len(backups) > w.maxBackups {
+		for _, b := range backups[w.maxBackups:] {
+			if err := os.Remove(b.path); err != nil {
+				fmt.Fprintf(os.Stderr, "logger: failed to remove excess backup %q: %v\n", b.path, err)
+			}
+		}
+	}
+}
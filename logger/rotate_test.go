@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingWriterRotatesOnceSizeIsExceeded(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+
+	w := &rotatingWriter{path: path, maxSizeBytes: 10, maxBackups: 5}
+	if err := w.open(); err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+
+	if _, err := w.Write([]byte("0123456789")); err != nil { // exactly at the limit, no rotation yet
+		t.Fatalf("Write() error = %v", err)
+	}
+	if countBackups(t, dir) != 0 {
+		t.Fatalf("expected no backups yet, got %d", countBackups(t, dir))
+	}
+
+	if _, err := w.Write([]byte("x")); err != nil { // pushes size past the limit, should rotate first
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := countBackups(t, dir); got != 1 {
+		t.Fatalf("expected 1 backup after exceeding maxSizeBytes, got %d", got)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "x" {
+		t.Fatalf("active file content = %q, want %q", data, "x")
+	}
+}
+
+func TestRotatingWriterPrunesByMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+
+	w := &rotatingWriter{path: path, maxSizeBytes: 1, maxBackups: 2}
+	if err := w.open(); err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte("xx")); err != nil { // always exceeds maxSizeBytes=1, forcing a rotation each time
+			t.Fatalf("Write() #%d error = %v", i, err)
+		}
+		// Rotation timestamps have millisecond granularity; force them apart so
+		// successive backups don't collide on the same file name.
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	if got := countBackups(t, dir); got != 2 {
+		t.Fatalf("expected maxBackups=2 to cap retained backups, got %d", got)
+	}
+}
+
+func TestRotatingWriterReopensAfterFailedRename(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+
+	w := &rotatingWriter{path: path, maxSizeBytes: 1, maxBackups: 5}
+	if err := w.open(); err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+
+	orig := renameFile
+	renameFile = func(string, string) error { return errors.New("simulated rename failure") }
+	defer func() { renameFile = orig }()
+
+	if _, err := w.Write([]byte("xx")); err != nil {
+		t.Fatalf("Write() error = %v, want the writer to stay usable despite a failed rotation rename", err)
+	}
+	if _, err := w.Write([]byte("yy")); err != nil {
+		t.Fatalf("second Write() error = %v, want the file to have been reopened after the failed rename", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "xxyy" {
+		t.Fatalf("active file content = %q, want %q (writes should keep appending, not vanish)", data, "xxyy")
+	}
+	if countBackups(t, dir) != 0 {
+		t.Fatalf("expected no backups to have been created, got %d", countBackups(t, dir))
+	}
+}
+
+func countBackups(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	var n int
+	for _, e := range entries {
+		if e.Name() != "test.log" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,77 @@
+// Package syslog implements a logger.Hook that forwards entries to a network
+// syslog server using RFC 5424 framing.
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/AnhaoROMA/webCrawler/logger"
+)
+
+// facilityUser is the RFC 5424 facility code for user-level messages.
+const facilityUser = 1
+
+// Hook dials a syslog server once and writes one RFC 5424 formatted message
+// per Fire call. It is safe for concurrent use.
+type Hook struct {
+	levels  []string
+	appName string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// New dials addr over network ("udp", "tcp", ...) and returns a Hook that
+// forwards entries whose level is in levels. appName is sent as the RFC 5424
+// APP-NAME field.
+func New(network, addr, appName string, levels []string) (*Hook, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("syslog: dial %s %s: %w", network, addr, err)
+	}
+	return &Hook{levels: levels, appName: appName, conn: conn}, nil
+}
+
+// Levels implements logger.Hook.
+func (h *Hook) Levels() []string {
+	return h.levels
+}
+
+// Fire implements logger.Hook.
+func (h *Hook) Fire(entry logger.Entry) error {
+	pri := facilityUser*8 + severity(entry.Level)
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	message := fmt.Sprintf("<%d>1 %s %s %s - - - %s (%s:%d)\n",
+		pri, entry.Time.Format(time.RFC3339), hostname, h.appName, entry.Msg, entry.File, entry.Line)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.conn.Write([]byte(message))
+	return err
+}
+
+func severity(level string) int {
+	switch level {
+	case "PANIC":
+		return 0 // Emergency
+	case "FATAL":
+		return 2 // Critical
+	case "ERROR":
+		return 3 // Error
+	case "WARN":
+		return 4 // Warning
+	case "INFO":
+		return 6 // Informational
+	case "DEBUG", "TRACE":
+		return 7 // Debug
+	default:
+		return 6
+	}
+}